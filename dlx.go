@@ -6,14 +6,119 @@
 // The algorithm is described in the "Dancing Links" paper by Donald Knuth
 // published in "Millennial Perspectives in Computer Science. P159. Volume 187"
 // (2000).
+//
+// Matrix also supports Knuth's exact-cover-with-colors variant (Algorithm
+// C, TAOCP 7.2.2.1) via secondary columns and colored items: see
+// PushSecondaryHead and PushColorItem.
 package dlx
 
+import (
+	"context"
+	"math/rand"
+)
+
 // Matrix represents a sparse matrix.
 // The zero value of a Matrix is an empty matrix ready to use.
 type Matrix struct {
 	h         Element
+	s         Element
 	o         []*Element
 	solutions [][]string
+
+	// MaxSolutions caps the number of solutions Solve, SolveFunc and
+	// SolveChan will produce. Zero or negative means unlimited.
+	MaxSolutions int
+
+	columnChooser ColumnChooser
+	rowOrder      RowOrder
+}
+
+// ColumnChooser selects which column search branches on next, given the
+// first head in the ring returned by Matrix.Head. Implementations walk
+// the ring with Element.Right and must return one of its elements.
+type ColumnChooser func(head *Element) *Element
+
+// ChooseFirst always selects the first column in header order.
+func ChooseFirst(head *Element) *Element {
+	return head
+}
+
+// ChooseMinSize selects the column with the fewest remaining rows,
+// Knuth's minimum-remaining-values ("S") heuristic, breaking ties by
+// header order. This is Matrix's default chooser.
+func ChooseMinSize(head *Element) *Element {
+	var c *Element
+	s := uint64(18446744073709551615)
+	for ce := head; ce != nil; ce = ce.Right() {
+		ces := ce.Value.(Head).size
+		if ces < s {
+			c = ce
+			s = ces
+		}
+	}
+	return c
+}
+
+// ChooseRandom returns a ColumnChooser that selects uniformly at random
+// among the remaining columns, drawing from r. Useful for randomized
+// restarts on problems where the MRV heuristic is a poor fit.
+func ChooseRandom(r *rand.Rand) ColumnChooser {
+	return func(head *Element) *Element {
+		var c *Element
+		n := 0
+		for ce := head; ce != nil; ce = ce.Right() {
+			n++
+			if r.Intn(n) == 0 {
+				c = ce
+			}
+		}
+		return c
+	}
+}
+
+// SetColumnChooser sets the strategy Matrix uses to pick which column to
+// branch on next during search. The zero value uses ChooseMinSize.
+func (m *Matrix) SetColumnChooser(c ColumnChooser) *Matrix {
+	m.columnChooser = c
+	return m
+}
+
+// RowOrder reorders the rows of a chosen column before search tries them
+// in turn, without changing which rows are tried.
+type RowOrder func(rows []*Element) []*Element
+
+// RowOrderInsertion tries rows in the order they were pushed. This is
+// Matrix's default row order.
+var RowOrderInsertion RowOrder = func(rows []*Element) []*Element {
+	return rows
+}
+
+// RowOrderReverse tries rows in the opposite of insertion order.
+var RowOrderReverse RowOrder = func(rows []*Element) []*Element {
+	out := make([]*Element, len(rows))
+	for i, r := range rows {
+		out[len(rows)-1-i] = r
+	}
+	return out
+}
+
+// RowOrderShuffled returns a RowOrder that tries rows in a random order
+// drawn from r, enabling random-restart solvers on top of a single
+// matrix.
+func RowOrderShuffled(r *rand.Rand) RowOrder {
+	return func(rows []*Element) []*Element {
+		out := make([]*Element, len(rows))
+		copy(out, rows)
+		r.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+		return out
+	}
+}
+
+// SetRowOrder sets the order in which Matrix tries the rows of a chosen
+// column during search. The zero value uses RowOrderInsertion.
+func (m *Matrix) SetRowOrder(o RowOrder) *Matrix {
+	m.rowOrder = o
+	return m
 }
 
 // Init initializes the matrix, empty and ready to use.
@@ -23,6 +128,11 @@ func (m *Matrix) Init() *Matrix {
 	m.h.left = &m.h
 	m.h.right = &m.h
 	m.h.column = &m.h
+	m.s.up = &m.s
+	m.s.down = &m.s
+	m.s.left = &m.s
+	m.s.right = &m.s
+	m.s.column = &m.s
 	m.o = nil
 	m.solutions = nil
 	return m
@@ -39,6 +149,18 @@ func (m *Matrix) Head() *Element {
 	return m.h.right
 }
 
+// SecondaryHead returns the first secondary Head element from the
+// matrix, or nil if none have been pushed. Secondary heads are not
+// linked into the ring returned by Head, so they are never chosen by
+// search, but rows that reference them are still tracked via cover,
+// uncover, purify and unpurify.
+func (m *Matrix) SecondaryHead() *Element {
+	if m.s.right == &m.s {
+		return nil
+	}
+	return m.s.right
+}
+
 // Lazy initialization
 func (m *Matrix) lazyInit() {
 	if m.h.right == nil {
@@ -46,9 +168,9 @@ func (m *Matrix) lazyInit() {
 	}
 }
 
-// Helper function to insert a Head element into the matrix and returns a
-// pointer to the element.
-func (m *Matrix) insertHead(e, at *Element) *Element {
+// Helper function to insert a Head element into the matrix, linked into
+// the ring rooted at root, and returns a pointer to the element.
+func (m *Matrix) insertHead(e, at, root *Element) *Element {
 	// Positional pointers
 	n := at.right
 	at.right = e
@@ -60,13 +182,14 @@ func (m *Matrix) insertHead(e, at *Element) *Element {
 	// Structural pointers
 	e.matrix = m
 	e.column = e
+	e.root = root
 	return e
 }
 
-// Helper function to insert a given value into the header of the matrix at the
-// given head element.
-func (m *Matrix) insertHeadValue(v interface{}, at *Element) *Element {
-	return m.insertHead(&Element{Value: v}, at)
+// Helper function to insert a given value into the header ring rooted at
+// root, at the given head element.
+func (m *Matrix) insertHeadValue(v interface{}, at, root *Element) *Element {
+	return m.insertHead(&Element{Value: v}, at, root)
 }
 
 // PushHead pushes a Head element onto the matrix with the given name and
@@ -74,7 +197,20 @@ func (m *Matrix) insertHeadValue(v interface{}, at *Element) *Element {
 func (m *Matrix) PushHead(name string) *Element {
 	m.lazyInit()
 	head := Head{name, 0}
-	return m.insertHeadValue(head, m.h.left)
+	return m.insertHeadValue(head, m.h.left, &m.h)
+}
+
+// PushSecondaryHead pushes a Head element onto the matrix with the given
+// name, linked into the secondary ring rather than the primary one, and
+// returns a pointer to the element. Secondary columns model items that
+// must be covered at most once: search never selects them, so a solution
+// may leave some secondary columns uncovered, while committing a row
+// still covers (or purifies) each secondary column that row touches.
+// Use PushColorItem to add rows under a secondary column.
+func (m *Matrix) PushSecondaryHead(name string) *Element {
+	m.lazyInit()
+	head := Head{name, 0}
+	return m.insertHeadValue(head, m.s.left, &m.s)
 }
 
 // Inserts an element at the given row and column and returns a pointer to the
@@ -110,8 +246,23 @@ func (m *Matrix) PushItem(row, colHead *Element) *Element {
 	return m.insertItem(&Element{Value: true}, row, colHead.up)
 }
 
-// Finds any solutions within the matrix at the given level.
-func (m *Matrix) search(k int) {
+// PushColorItem pushes the given row onto the matrix under the given
+// secondary column head element, tagged with color, and returns a
+// pointer to the row element. When a row committed by search carries a
+// colored item on a secondary column, the column is purified rather than
+// covered: only rows whose color on that column differs from color are
+// hidden, so rows sharing a matching color may coexist in a solution.
+func (m *Matrix) PushColorItem(row, colHead *Element, color interface{}) *Element {
+	e := m.insertItem(&Element{Value: true}, row, colHead.up)
+	e.color = color
+	return e
+}
+
+// Finds any solutions within the matrix at the given level, invoking
+// visit with each one as it is discovered. visit reports whether the
+// search should continue; search itself returns that same verdict so an
+// abort propagates back up through the recursion.
+func (m *Matrix) search(k int, visit func(solution []string) bool) bool {
 	if m.Head() == nil {
 		solStr := make([]string, len(m.o))
 		for i := range m.o {
@@ -123,50 +274,124 @@ func (m *Matrix) search(k int) {
 			}
 			solStr[i] = rowStr
 		}
-		m.solutions = append(m.solutions, solStr)
-		return
+		return visit(solStr)
 	}
 	c := m.getColumn()
 	m.cover(c)
+
+	var rows []*Element
 	for r := c.Down(); r != c; r = r.Down() {
+		rows = append(rows, r)
+	}
+	if m.rowOrder != nil {
+		rows = m.rowOrder(rows)
+	}
+
+	// purified reports whether a row already chosen above this level has
+	// an item on j's column; that column is then purified already, and
+	// purifying it again would hide its rows a second time.
+	purified := func(j *Element) bool {
+		for _, o := range m.o[:k] {
+			for q := o.Right(); q != o; q = q.Right() {
+				if q.column == j.column {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	cont := true
+	for _, r := range rows {
+		if !cont {
+			break
+		}
 		m.o = append(m.o, r)
 		for j := r.Right(); j != r; j = j.Right() {
-			m.cover(j.column)
+			if j.color != nil {
+				if !purified(j) {
+					m.purify(j)
+				}
+			} else {
+				m.cover(j.column)
+			}
 		}
-		m.search(k + 1)
-		r = m.o[k]
+		cont = m.search(k+1, visit)
 
 		m.o[k] = nil
 		m.o = m.o[0 : len(m.o)-1]
 
-		c = r.column
 		for j := r.Left(); j != r; j = j.Left() {
-			m.uncover(j.column)
+			if j.color != nil {
+				if !purified(j) {
+					m.unpurify(j)
+				}
+			} else {
+				m.uncover(j.column)
+			}
 		}
 	}
 	m.uncover(c)
+	return cont
 }
 
 // Solve invokes a search for solutions from the root (level 0) and returns
 // a slice of all found solutions as a slice of strings denoting valid
 // constraint options that exactly covers the problem space.
 func (m *Matrix) Solve() [][]string {
-	m.search(0)
+	m.SolveFunc(func(solution []string) bool {
+		m.solutions = append(m.solutions, solution)
+		return true
+	})
 	return m.solutions
 }
 
-// Returns a pointer to the head element of the column with the smallest size.
-func (m *Matrix) getColumn() *Element {
-	var c *Element
-	s := uint64(18446744073709551615)
-	for ce := m.Head(); ce != nil; ce = ce.Right() {
-		ces := ce.Value.(Head).size
-		if ces < s {
-			c = ce
-			s = ces
+// SolveFunc invokes fn for each solution as it is discovered during
+// search, in depth-first order, without accumulating them in memory.
+// It stops as soon as fn returns false, or once MaxSolutions solutions
+// have been produced (when MaxSolutions is positive).
+func (m *Matrix) SolveFunc(fn func(solution []string) bool) {
+	n := 0
+	m.search(0, func(solution []string) bool {
+		n++
+		if !fn(solution) {
+			return false
 		}
+		return m.MaxSolutions <= 0 || n < m.MaxSolutions
+	})
+}
+
+// SolveChan runs search in its own goroutine and streams solutions on
+// the returned channel, which is closed once search completes or stops
+// on cancellation. ctx is checked only when a solution is ready to send,
+// so after ctx is canceled search runs on until it finds its next
+// solution or completes. Consuming fewer solutions than are found and
+// abandoning the channel leaks the goroutine unless ctx is eventually
+// canceled.
+func (m *Matrix) SolveChan(ctx context.Context) <-chan []string {
+	out := make(chan []string)
+	go func() {
+		defer close(out)
+		m.SolveFunc(func(solution []string) bool {
+			select {
+			case out <- solution:
+				return ctx.Err() == nil
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return out
+}
+
+// Returns a pointer to the head element chosen by the matrix's
+// ColumnChooser, or ChooseMinSize if none was set.
+func (m *Matrix) getColumn() *Element {
+	chooser := m.columnChooser
+	if chooser == nil {
+		chooser = ChooseMinSize
 	}
-	return c
+	return chooser(m.Head())
 }
 
 // The cover operation of algorithm X.
@@ -195,6 +420,41 @@ func (m *Matrix) uncover(c *Element) {
 	c.left.right = c
 }
 
+// The purify operation of algorithm C: within j's column, fully hides
+// every row whose color differs from j's (removing it from every other
+// column it belongs to, exactly as cover's inner loop does), leaving
+// rows that share j's color (including j itself) untouched and still
+// linked everywhere, including in j's own column.
+func (m *Matrix) purify(j *Element) {
+	c := j.column
+	for i := c.Down(); i != c; i = i.Down() {
+		if i == j || i.color == j.color {
+			continue
+		}
+		for q := i.Right(); q != i; q = q.Right() {
+			q.down.up = q.up
+			q.up.down = q.down
+			q.column.Value = Head{q.column.Value.(Head).name, q.column.Value.(Head).size - 1}
+		}
+	}
+}
+
+// The unpurify operation of algorithm C: restores, in reverse order,
+// the rows hidden by a prior call to purify on j.
+func (m *Matrix) unpurify(j *Element) {
+	c := j.column
+	for i := c.Up(); i != c; i = i.Up() {
+		if i == j || i.color == j.color {
+			continue
+		}
+		for q := i.Left(); q != i; q = q.Left() {
+			q.column.Value = Head{q.column.Value.(Head).name, q.column.Value.(Head).size + 1}
+			q.down.up = q
+			q.up.down = q
+		}
+	}
+}
+
 // Element is an element of a matrix. Contains a Value interface{}.
 type Element struct {
 	// Pointers in the matrix of elements.
@@ -204,12 +464,37 @@ type Element struct {
 	// The matrix to which the element belongs.
 	matrix *Matrix
 
+	// root is the sentinel of the ring this element terminates Up,
+	// Down, Left and Right traversal against: &matrix.h for primary
+	// heads, &matrix.s for secondary heads, nil for every other
+	// element (rows and columns never wrap back to either header ring).
+	root *Element
+
+	// color tags a row's item on a secondary column for algorithm C;
+	// nil means the item participates in an ordinary cover/uncover.
+	color interface{}
+
 	Value interface{}
 }
 
+// Color returns the color tag set by PushColorItem, or nil if the
+// element was not pushed as a colored item.
+func (e *Element) Color() interface{} {
+	return e.color
+}
+
+// sentinel returns the ring root Up, Down, Left and Right must compare
+// against to detect wraparound for e.
+func (e *Element) sentinel() *Element {
+	if e.root != nil {
+		return e.root
+	}
+	return &e.matrix.h
+}
+
 // Up returns the above matrix element or nil.
 func (e *Element) Up() *Element {
-	if p := e.up; e.matrix != nil && p != &e.matrix.h {
+	if p := e.up; e.matrix != nil && p != e.sentinel() {
 		return p
 	}
 	return nil
@@ -217,7 +502,7 @@ func (e *Element) Up() *Element {
 
 // Down returns the below matrix element or nil.
 func (e *Element) Down() *Element {
-	if p := e.down; e.matrix != nil && p != &e.matrix.h {
+	if p := e.down; e.matrix != nil && p != e.sentinel() {
 		return p
 	}
 	return nil
@@ -225,7 +510,7 @@ func (e *Element) Down() *Element {
 
 // Left returns the left matrix element or nil.
 func (e *Element) Left() *Element {
-	if p := e.left; e.matrix != nil && p != &e.matrix.h {
+	if p := e.left; e.matrix != nil && p != e.sentinel() {
 		return p
 	}
 	return nil
@@ -233,7 +518,7 @@ func (e *Element) Left() *Element {
 
 // Right returns the right matrix element or nil.
 func (e *Element) Right() *Element {
-	if p := e.right; e.matrix != nil && p != &e.matrix.h {
+	if p := e.right; e.matrix != nil && p != e.sentinel() {
 		return p
 	}
 	return nil
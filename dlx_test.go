@@ -0,0 +1,273 @@
+package dlx
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+// buildTwoSolutionMatrix constructs a small exact-cover matrix over
+// columns A and B with two independent solutions: {row1, row2} (A and B
+// covered separately) and {row3} (A and B covered together).
+func buildTwoSolutionMatrix() *Matrix {
+	m := New()
+	a := m.PushHead("A")
+	b := m.PushHead("B")
+
+	m.PushItem(nil, a)
+	m.PushItem(nil, b)
+
+	row3 := m.PushItem(nil, a)
+	m.PushItem(row3, b)
+
+	return m
+}
+
+// buildColorMatrix constructs the P1/P2 primary, S secondary example from
+// Knuth's algorithm C write-up: row1={P1,S=red}, row2={P2,S=red},
+// row3={P2,S=blue}. Covering P1 then P2 admits either row1+row2 (both red
+// on S, coexisting under purify) or row1+row3 (conflicting colors, so row3
+// must be excluded) -- exactly one solution, not two.
+func buildColorMatrix() *Matrix {
+	m := New()
+	p1 := m.PushHead("P1")
+	p2 := m.PushHead("P2")
+	s := m.PushSecondaryHead("S")
+
+	row1 := m.PushItem(nil, p1)
+	m.PushColorItem(row1, s, "red")
+
+	row2 := m.PushItem(nil, p2)
+	m.PushColorItem(row2, s, "red")
+
+	row3 := m.PushItem(nil, p2)
+	m.PushColorItem(row3, s, "blue")
+
+	return m
+}
+
+func TestSolveColors(t *testing.T) {
+	m := buildColorMatrix()
+	sols := m.Solve()
+	if len(sols) != 1 {
+		t.Fatalf("expected 1 solution, got %d: %+v", len(sols), sols)
+	}
+}
+
+func TestSecondaryHeadTraversal(t *testing.T) {
+	m := New()
+	m.PushSecondaryHead("S1")
+	m.PushSecondaryHead("S2")
+
+	var names []string
+	for e := m.SecondaryHead(); e != nil; e = e.Right() {
+		names = append(names, e.Value.(Head).Name())
+	}
+	if len(names) != 2 || names[0] != "S1" || names[1] != "S2" {
+		t.Fatalf("expected [S1 S2], got %v", names)
+	}
+}
+
+func TestSecondaryHeadEmpty(t *testing.T) {
+	m := New()
+	if h := m.SecondaryHead(); h != nil {
+		t.Fatalf("expected nil SecondaryHead on empty matrix, got %v", h)
+	}
+}
+
+func TestPushColorItemColor(t *testing.T) {
+	m := New()
+	s := m.PushSecondaryHead("S")
+	row := m.PushItem(nil, s)
+	e := m.PushColorItem(row, s, "red")
+	if e.Color() != "red" {
+		t.Fatalf("Color() = %v, want red", e.Color())
+	}
+}
+
+func TestSolveFunc(t *testing.T) {
+	m := buildTwoSolutionMatrix()
+	var got []string
+	m.SolveFunc(func(solution []string) bool {
+		got = append(got, solution[0])
+		return true
+	})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 solutions, got %d: %v", len(got), got)
+	}
+}
+
+func TestSolveFuncStopsEarly(t *testing.T) {
+	m := buildTwoSolutionMatrix()
+	n := 0
+	m.SolveFunc(func(solution []string) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Fatalf("expected search to stop after 1 solution, got %d", n)
+	}
+}
+
+func TestMaxSolutions(t *testing.T) {
+	m := buildTwoSolutionMatrix()
+	m.MaxSolutions = 1
+	sols := m.Solve()
+	if len(sols) != 1 {
+		t.Fatalf("expected MaxSolutions to cap at 1, got %d", len(sols))
+	}
+}
+
+func TestSolveChan(t *testing.T) {
+	m := buildTwoSolutionMatrix()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got [][]string
+	for solution := range m.SolveChan(ctx) {
+		got = append(got, solution)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 solutions, got %d: %v", len(got), got)
+	}
+}
+
+func TestSolveChanCancel(t *testing.T) {
+	m := buildTwoSolutionMatrix()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := m.SolveChan(ctx)
+	sol, ok := <-ch
+	if !ok {
+		t.Fatal("expected at least one solution before cancel")
+	}
+	_ = sol
+	cancel()
+
+	for range ch {
+	}
+}
+
+// buildChooserMatrix constructs three independent columns, A, B and C,
+// with distinct sizes (3, 1 and 2 rows respectively) so ChooseFirst and
+// ChooseMinSize are observably different.
+func buildChooserMatrix() *Matrix {
+	m := New()
+	a := m.PushHead("A")
+	b := m.PushHead("B")
+	c := m.PushHead("C")
+
+	for i := 0; i < 3; i++ {
+		m.PushItem(nil, a)
+	}
+	m.PushItem(nil, b)
+	for i := 0; i < 2; i++ {
+		m.PushItem(nil, c)
+	}
+	return m
+}
+
+func TestChooseFirst(t *testing.T) {
+	m := buildChooserMatrix()
+	if got := ChooseFirst(m.Head()).Value.(Head).Name(); got != "A" {
+		t.Fatalf("ChooseFirst = %s, want A", got)
+	}
+}
+
+func TestChooseMinSize(t *testing.T) {
+	m := buildChooserMatrix()
+	if got := ChooseMinSize(m.Head()).Value.(Head).Name(); got != "B" {
+		t.Fatalf("ChooseMinSize = %s, want B", got)
+	}
+}
+
+func TestChooseRandomDeterministic(t *testing.T) {
+	m := buildChooserMatrix()
+	want := ChooseRandom(rand.New(rand.NewSource(1)))(m.Head()).Value.(Head).Name()
+	got := ChooseRandom(rand.New(rand.NewSource(1)))(m.Head()).Value.(Head).Name()
+	if got != want {
+		t.Fatalf("ChooseRandom with the same seed returned %s, then %s", want, got)
+	}
+}
+
+func TestSetColumnChooser(t *testing.T) {
+	m := buildChooserMatrix()
+	var first string
+	m.SetColumnChooser(func(head *Element) *Element {
+		c := ChooseMinSize(head)
+		if first == "" {
+			first = c.Value.(Head).Name()
+		}
+		return c
+	})
+	m.Solve()
+	if first != "B" {
+		t.Fatalf("SetColumnChooser was not consulted by search: first column = %s, want B", first)
+	}
+}
+
+// buildRowOrderMatrix constructs a single column A with three rows, each
+// tagged with a distinct secondary item so the order search tries them
+// in is recoverable from the resulting solution strings.
+func buildRowOrderMatrix() *Matrix {
+	m := New()
+	a := m.PushHead("A")
+	for _, tag := range []string{"tag:1", "tag:2", "tag:3"} {
+		row := m.PushItem(nil, a)
+		m.PushItem(row, m.PushSecondaryHead(tag))
+	}
+	return m
+}
+
+func tagOrder(sols [][]string) []string {
+	tags := make([]string, len(sols))
+	for i, sol := range sols {
+		tags[i] = sol[0][2:] // strip the leading "A " column name
+	}
+	return tags
+}
+
+func TestRowOrderInsertionDefault(t *testing.T) {
+	m := buildRowOrderMatrix()
+	got := tagOrder(m.Solve())
+	want := []string{"tag:1", "tag:2", "tag:3"}
+	if !equalStrings(got, want) {
+		t.Fatalf("default row order = %v, want %v", got, want)
+	}
+}
+
+func TestRowOrderReverse(t *testing.T) {
+	m := buildRowOrderMatrix()
+	m.SetRowOrder(RowOrderReverse)
+	got := tagOrder(m.Solve())
+	want := []string{"tag:3", "tag:2", "tag:1"}
+	if !equalStrings(got, want) {
+		t.Fatalf("reversed row order = %v, want %v", got, want)
+	}
+}
+
+func TestRowOrderShuffledDeterministic(t *testing.T) {
+	m1 := buildRowOrderMatrix()
+	m1.SetRowOrder(RowOrderShuffled(rand.New(rand.NewSource(1))))
+	got1 := tagOrder(m1.Solve())
+
+	m2 := buildRowOrderMatrix()
+	m2.SetRowOrder(RowOrderShuffled(rand.New(rand.NewSource(1))))
+	got2 := tagOrder(m2.Solve())
+
+	if !equalStrings(got1, got2) {
+		t.Fatalf("RowOrderShuffled with the same seed returned %v, then %v", got1, got2)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+// Copyright 2014 Zanicar. All rights reserved.
+
+// Utilizes a BSD-3-Clause license. Refer to the included LICENSE file for details.
+
+// Package packing builds exact-cover matrices for polyomino-style
+// shape-packing problems, the puzzle class Knuth originally introduced
+// Dancing Links to solve, and translates solutions back into concrete
+// placements.
+package packing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zanicar/dlx"
+)
+
+// Symmetry controls which rotations and reflections of a Piece are
+// considered as distinct candidate placements.
+type Symmetry int
+
+const (
+	// SymmetryIdentity considers only a piece's shape as given.
+	SymmetryIdentity Symmetry = iota
+	// SymmetryRotations considers a piece's four rotations (the cyclic
+	// group C4).
+	SymmetryRotations
+	// SymmetryDihedral considers a piece's rotations and reflections
+	// (the dihedral group D4).
+	SymmetryDihedral
+)
+
+// Piece is a named polyomino expressed as a set of [row, col] offsets.
+type Piece struct {
+	Name  string
+	Cells [][2]int
+}
+
+// Board is a container expressed as a set of [row, col] cells pieces may
+// be placed into.
+type Board struct {
+	Cells [][2]int
+}
+
+// Placement is a single piece placed at a concrete position on the
+// board, as one of the rows chosen by a solution.
+type Placement struct {
+	Piece string
+	Cells [][2]int
+}
+
+// Options controls how Solve builds candidate placements.
+type Options struct {
+	// Unique requires each piece to be used exactly once; when false,
+	// pieces may be reused any number of times.
+	Unique bool
+	// Symmetry controls which orientations of each piece are generated.
+	Symmetry Symmetry
+}
+
+// Solve packs pieces onto board and returns the placements of the first
+// solution found, or nil if the board cannot be exactly covered.
+func Solve(board Board, pieces []Piece, opts Options) []Placement {
+	m := dlx.New()
+
+	cellHead := make(map[[2]int]*dlx.Element, len(board.Cells))
+	for _, c := range board.Cells {
+		cellHead[c] = m.PushHead(fmt.Sprintf("cell:%d:%d", c[0], c[1]))
+	}
+
+	// Indexed by position in pieces, not by name: two entries sharing a
+	// Name (two copies of the same physical piece) must each get their
+	// own column, or the second would silently overwrite the first's in
+	// a name-keyed map and leave it an unreachable, permanently empty
+	// column that dead-ends the search as soon as it is chosen.
+	var pieceHeads []*dlx.Element
+	if opts.Unique {
+		pieceHeads = make([]*dlx.Element, len(pieces))
+		for i, p := range pieces {
+			pieceHeads[i] = m.PushHead(fmt.Sprintf("piece:%d:%s", i, p.Name))
+		}
+	}
+
+	id := 0
+	for pi, p := range pieces {
+		if !validPiece(p) {
+			continue
+		}
+		for _, shape := range orientations(p.Cells, opts.Symmetry) {
+			for _, origin := range board.Cells {
+				cells, ok := place(shape, origin, cellHead)
+				if !ok {
+					continue
+				}
+				row := m.PushItem(nil, cellHead[cells[0]])
+				for _, c := range cells[1:] {
+					m.PushItem(row, cellHead[c])
+				}
+				if opts.Unique {
+					m.PushItem(row, pieceHeads[pi])
+				}
+				// A secondary column uniquely tagging this row lets the
+				// solution be translated back into a Placement without
+				// constraining the search: search never has to cover it.
+				tag := m.PushSecondaryHead(fmt.Sprintf("placement:%d:%s", id, p.Name))
+				m.PushItem(row, tag)
+				id++
+			}
+		}
+	}
+
+	var placements []Placement
+	m.SolveFunc(func(solution []string) bool {
+		for _, rowStr := range solution {
+			if p, ok := parsePlacement(rowStr); ok {
+				placements = append(placements, p)
+			}
+		}
+		return false
+	})
+	return placements
+}
+
+// place translates shape to origin and reports the absolute cells, or
+// false if any of them fall outside the board.
+func place(shape [][2]int, origin [2]int, board map[[2]int]*dlx.Element) ([][2]int, bool) {
+	cells := make([][2]int, len(shape))
+	for i, c := range shape {
+		abs := [2]int{c[0] + origin[0], c[1] + origin[1]}
+		if _, ok := board[abs]; !ok {
+			return nil, false
+		}
+		cells[i] = abs
+	}
+	return cells, true
+}
+
+// parsePlacement recovers a Placement from a dlx solution row, expressed
+// as the names of the columns it covers.
+func parsePlacement(rowStr string) (Placement, bool) {
+	var cells [][2]int
+	var name string
+	for _, field := range strings.Fields(rowStr) {
+		switch {
+		case strings.HasPrefix(field, "cell:"):
+			var r, c int
+			fmt.Sscanf(field[len("cell:"):], "%d:%d", &r, &c)
+			cells = append(cells, [2]int{r, c})
+		case strings.HasPrefix(field, "placement:"):
+			if parts := strings.SplitN(field[len("placement:"):], ":", 2); len(parts) == 2 {
+				name = parts[1]
+			}
+		}
+	}
+	if name == "" {
+		return Placement{}, false
+	}
+	return Placement{Piece: name, Cells: cells}, true
+}
+
+// validPiece reports whether p has a usable shape: at least one cell and
+// no cell repeated. orientations and normalize assume both, so a piece
+// failing this check contributes no rows rather than panicking.
+func validPiece(p Piece) bool {
+	if len(p.Cells) == 0 {
+		return false
+	}
+	seen := make(map[[2]int]bool, len(p.Cells))
+	for _, c := range p.Cells {
+		if seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return true
+}
+
+// orientations returns the distinct shapes obtained by rotating cells
+// (when sym is SymmetryRotations or SymmetryDihedral) and, if sym is
+// SymmetryDihedral, reflecting them, each normalized to start at the
+// origin. For SymmetryIdentity it returns only the normalized cells.
+func orientations(cells [][2]int, sym Symmetry) [][][2]int {
+	raw := [][][2]int{normalize(cells)}
+	if sym == SymmetryRotations || sym == SymmetryDihedral {
+		raw = raw[:0]
+		cur := cells
+		for i := 0; i < 4; i++ {
+			raw = append(raw, normalize(cur))
+			cur = rotate(cur)
+		}
+	}
+	if sym == SymmetryDihedral {
+		cur := reflect(cells)
+		for i := 0; i < 4; i++ {
+			raw = append(raw, normalize(cur))
+			cur = rotate(cur)
+		}
+	}
+
+	seen := make(map[string]bool, len(raw))
+	var out [][][2]int
+	for _, shape := range raw {
+		k := shapeKey(shape)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, shape)
+	}
+	return out
+}
+
+// rotate turns cells a quarter turn about the origin.
+func rotate(cells [][2]int) [][2]int {
+	out := make([][2]int, len(cells))
+	for i, c := range cells {
+		out[i] = [2]int{c[1], -c[0]}
+	}
+	return out
+}
+
+// reflect mirrors cells across the row axis.
+func reflect(cells [][2]int) [][2]int {
+	out := make([][2]int, len(cells))
+	for i, c := range cells {
+		out[i] = [2]int{c[0], -c[1]}
+	}
+	return out
+}
+
+// normalize translates cells so its minimum row and column are zero and
+// sorts them, giving a canonical form suitable for deduplication.
+func normalize(cells [][2]int) [][2]int {
+	minR, minC := cells[0][0], cells[0][1]
+	for _, c := range cells {
+		if c[0] < minR {
+			minR = c[0]
+		}
+		if c[1] < minC {
+			minC = c[1]
+		}
+	}
+	out := make([][2]int, len(cells))
+	for i, c := range cells {
+		out[i] = [2]int{c[0] - minR, c[1] - minC}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i][0] != out[j][0] {
+			return out[i][0] < out[j][0]
+		}
+		return out[i][1] < out[j][1]
+	})
+	return out
+}
+
+// shapeKey returns a string uniquely identifying a normalized shape.
+func shapeKey(cells [][2]int) string {
+	var b strings.Builder
+	for _, c := range cells {
+		fmt.Fprintf(&b, "%d,%d;", c[0], c[1])
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package packing
+
+import "testing"
+
+func TestSolveDominoes(t *testing.T) {
+	board := Board{Cells: [][2]int{
+		{0, 0}, {0, 1},
+		{1, 0}, {1, 1},
+	}}
+	domino := Piece{Name: "domino", Cells: [][2]int{{0, 0}, {0, 1}}}
+	placements := Solve(board, []Piece{domino}, Options{Unique: false, Symmetry: SymmetryRotations})
+	if len(placements) == 0 {
+		t.Fatal("expected a solution")
+	}
+	covered := map[[2]int]bool{}
+	for _, p := range placements {
+		for _, c := range p.Cells {
+			if covered[c] {
+				t.Fatalf("cell %v covered twice", c)
+			}
+			covered[c] = true
+		}
+	}
+	if len(covered) != 4 {
+		t.Fatalf("expected 4 cells covered, got %d", len(covered))
+	}
+}
+
+func TestSolveUniquePieces(t *testing.T) {
+	board := Board{Cells: [][2]int{
+		{0, 0}, {0, 1}, {0, 2},
+		{1, 0}, {1, 1}, {1, 2},
+	}}
+	pieces := []Piece{
+		{Name: "A", Cells: [][2]int{{0, 0}, {0, 1}, {1, 0}}},
+		{Name: "B", Cells: [][2]int{{0, 0}, {0, 1}, {1, 1}}},
+	}
+	placements := Solve(board, pieces, Options{Unique: true, Symmetry: SymmetryDihedral})
+	if len(placements) != len(pieces) {
+		t.Fatalf("expected %d placements, got %d: %+v", len(pieces), len(placements), placements)
+	}
+	names := map[string]bool{}
+	for _, p := range placements {
+		names[p.Piece] = true
+	}
+	if len(names) != len(pieces) {
+		t.Fatalf("expected all pieces used exactly once, got %v", names)
+	}
+}
+
+func TestSolveUniqueDuplicateNames(t *testing.T) {
+	board := Board{Cells: [][2]int{
+		{0, 0}, {0, 1}, {0, 2}, {0, 3},
+	}}
+	domino := Piece{Name: "domino", Cells: [][2]int{{0, 0}, {0, 1}}}
+	placements := Solve(board, []Piece{domino, domino}, Options{Unique: true})
+	if len(placements) != 2 {
+		t.Fatalf("expected 2 placements from 2 identically named pieces, got %d: %+v", len(placements), placements)
+	}
+	covered := map[[2]int]bool{}
+	for _, p := range placements {
+		for _, c := range p.Cells {
+			if covered[c] {
+				t.Fatalf("cell %v covered twice", c)
+			}
+			covered[c] = true
+		}
+	}
+	if len(covered) != 4 {
+		t.Fatalf("expected 4 cells covered, got %d", len(covered))
+	}
+}
+
+func TestSolveNoFit(t *testing.T) {
+	board := Board{Cells: [][2]int{{0, 0}}}
+	piece := Piece{Name: "big", Cells: [][2]int{{0, 0}, {0, 1}}}
+	placements := Solve(board, []Piece{piece}, Options{})
+	if placements != nil {
+		t.Fatalf("expected no solution, got %+v", placements)
+	}
+}
+
+func TestSolveEmptyPiece(t *testing.T) {
+	board := Board{Cells: [][2]int{{0, 0}}}
+	placements := Solve(board, []Piece{{Name: "x"}}, Options{})
+	if placements != nil {
+		t.Fatalf("expected no solution, got %+v", placements)
+	}
+}
+
+func TestSolveDuplicateCellPiece(t *testing.T) {
+	board := Board{Cells: [][2]int{{0, 0}, {0, 1}}}
+	piece := Piece{Name: "dup", Cells: [][2]int{{0, 0}, {0, 0}}}
+	placements := Solve(board, []Piece{piece}, Options{})
+	if placements != nil {
+		t.Fatalf("expected no solution, got %+v", placements)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2014 Zanicar. All rights reserved.
+
+// Utilizes a BSD-3-Clause license. Refer to the included LICENSE file for details.
+
+// Package sudoku builds exact-cover matrices for arbitrary N²×N² Sudoku
+// boards (N=3 for a standard puzzle) on top of dlx, and solves them.
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zanicar/dlx"
+)
+
+// ErrNoSolution is returned by SolveOne when the board has no solution.
+var ErrNoSolution = errors.New("sudoku: no solution")
+
+// Solve returns every solution for the given board as filled grids.
+// board must be size×size with size a perfect square (e.g. 9×9 for a
+// standard puzzle), with 0 denoting an empty cell and any other value
+// in [1, size] a given digit.
+func Solve(board [][]int) ([][][]int, error) {
+	m, size, err := buildMatrix(board)
+	if err != nil {
+		return nil, err
+	}
+	sols := m.Solve()
+	grids := make([][][]int, len(sols))
+	for i, sol := range sols {
+		grids[i] = gridFromSolution(sol, size)
+	}
+	return grids, nil
+}
+
+// SolveOne returns the first solution found for the given board, or
+// ErrNoSolution if it has none. board follows the same rules as Solve.
+func SolveOne(board [][]int) ([][]int, error) {
+	m, size, err := buildMatrix(board)
+	if err != nil {
+		return nil, err
+	}
+	sols := m.Solve()
+	if len(sols) == 0 {
+		return nil, ErrNoSolution
+	}
+	return gridFromSolution(sols[0], size), nil
+}
+
+// buildMatrix constructs the exact-cover matrix for board: one
+// cell-filled, row-has-digit, column-has-digit and box-has-digit column
+// per constraint, and one row per (r, c, d) candidate: a given cell gets
+// only its given digit, an empty cell gets every digit. Candidates that
+// clash with a given elsewhere in the same row, column or box are not
+// pruned here; the exact cover excludes them.
+func buildMatrix(board [][]int) (*dlx.Matrix, int, error) {
+	size := len(board)
+	boxSize := isqrt(size)
+	if size == 0 || boxSize*boxSize != size {
+		return nil, 0, errors.New("sudoku: board size must be a positive perfect square")
+	}
+	for _, row := range board {
+		if len(row) != size {
+			return nil, 0, errors.New("sudoku: board must be square")
+		}
+		for _, v := range row {
+			if v < 0 || v > size {
+				return nil, 0, fmt.Errorf("sudoku: digit %d out of range [0, %d]", v, size)
+			}
+		}
+	}
+
+	m := dlx.New()
+
+	cell := make([][]*dlx.Element, size)
+	rowDigit := make([][]*dlx.Element, size)
+	colDigit := make([][]*dlx.Element, size)
+	boxDigit := make([][]*dlx.Element, size)
+	for i := 0; i < size; i++ {
+		cell[i] = make([]*dlx.Element, size)
+		rowDigit[i] = make([]*dlx.Element, size+1)
+		colDigit[i] = make([]*dlx.Element, size+1)
+		boxDigit[i] = make([]*dlx.Element, size+1)
+		for c := 0; c < size; c++ {
+			cell[i][c] = m.PushHead(fmt.Sprintf("cell:%d:%d", i, c))
+		}
+		for d := 1; d <= size; d++ {
+			rowDigit[i][d] = m.PushHead(fmt.Sprintf("row:%d:%d", i, d))
+			colDigit[i][d] = m.PushHead(fmt.Sprintf("col:%d:%d", i, d))
+			boxDigit[i][d] = m.PushHead(fmt.Sprintf("box:%d:%d", i, d))
+		}
+	}
+
+	for r := 0; r < size; r++ {
+		for c := 0; c < size; c++ {
+			given := board[r][c]
+			lo, hi := 1, size
+			if given != 0 {
+				lo, hi = given, given
+			}
+			b := (r/boxSize)*boxSize + c/boxSize
+			for d := lo; d <= hi; d++ {
+				row := m.PushItem(nil, cell[r][c])
+				m.PushItem(row, rowDigit[r][d])
+				m.PushItem(row, colDigit[c][d])
+				m.PushItem(row, boxDigit[b][d])
+			}
+		}
+	}
+	return m, size, nil
+}
+
+// gridFromSolution translates a dlx solution, expressed as the names of
+// the columns each chosen row covers, back into a filled size×size grid.
+func gridFromSolution(solution []string, size int) [][]int {
+	grid := make([][]int, size)
+	for i := range grid {
+		grid[i] = make([]int, size)
+	}
+	for _, rowStr := range solution {
+		var r, c, d int
+		for _, field := range strings.Fields(rowStr) {
+			switch {
+			case strings.HasPrefix(field, "cell:"):
+				fmt.Sscanf(field[len("cell:"):], "%d:%d", &r, &c)
+			case strings.HasPrefix(field, "row:"):
+				fmt.Sscanf(field[len("row:"):], "%d:%d", &r, &d)
+			}
+		}
+		grid[r][c] = d
+	}
+	return grid
+}
+
+// isqrt returns the integer square root of n, or 0 if n is negative.
+func isqrt(n int) int {
+	if n < 0 {
+		return 0
+	}
+	r := 0
+	for r*r <= n {
+		r++
+	}
+	return r - 1
+}
@@ -0,0 +1,58 @@
+package sudoku
+
+import "testing"
+
+func TestSolveOne(t *testing.T) {
+	board := [][]int{
+		{5, 3, 0, 0, 7, 0, 0, 0, 0},
+		{6, 0, 0, 1, 9, 5, 0, 0, 0},
+		{0, 9, 8, 0, 0, 0, 0, 6, 0},
+		{8, 0, 0, 0, 6, 0, 0, 0, 3},
+		{4, 0, 0, 8, 0, 3, 0, 0, 1},
+		{7, 0, 0, 0, 2, 0, 0, 0, 6},
+		{0, 6, 0, 0, 0, 0, 2, 8, 0},
+		{0, 0, 0, 4, 1, 9, 0, 0, 5},
+		{0, 0, 0, 0, 8, 0, 0, 7, 9},
+	}
+	want := [][]int{
+		{5, 3, 4, 6, 7, 8, 9, 1, 2},
+		{6, 7, 2, 1, 9, 5, 3, 4, 8},
+		{1, 9, 8, 3, 4, 2, 5, 6, 7},
+		{8, 5, 9, 7, 6, 1, 4, 2, 3},
+		{4, 2, 6, 8, 5, 3, 7, 9, 1},
+		{7, 1, 3, 9, 2, 4, 8, 5, 6},
+		{9, 6, 1, 5, 3, 7, 2, 8, 4},
+		{2, 8, 7, 4, 1, 9, 6, 3, 5},
+		{3, 4, 5, 2, 8, 6, 1, 7, 9},
+	}
+
+	grid, err := SolveOne(board)
+	if err != nil {
+		t.Fatalf("SolveOne returned error: %v", err)
+	}
+	for r := range want {
+		for c := range want[r] {
+			if grid[r][c] != want[r][c] {
+				t.Fatalf("cell (%d,%d) = %d, want %d", r, c, grid[r][c], want[r][c])
+			}
+		}
+	}
+}
+
+func TestSolveOneNoSolution(t *testing.T) {
+	board := [][]int{
+		{1, 1, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	}
+	if _, err := SolveOne(board); err != ErrNoSolution {
+		t.Fatalf("SolveOne error = %v, want ErrNoSolution", err)
+	}
+}
+
+func TestSolveInvalidSize(t *testing.T) {
+	if _, err := Solve([][]int{{0, 0}, {0, 0}}); err == nil {
+		t.Fatal("Solve with a non-square-root board size should error")
+	}
+}